@@ -0,0 +1,83 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/djfritz/number"
+)
+
+// algCheck verifies identities that must hold exactly for the given
+// operation, regardless of the expected value in the test. Only the
+// symmetric rounding modes are run, so negation commutes with rounding, and
+// an exact zero sum or difference is +0.
+// Every operand is also checked to be unchanged by negating it twice. The
+// number package has no negation, so it is done by multiplying by -1, which
+// is exact. Zero operands are not negated, as number has no -0.
+func algCheck(s, op string, x []*number.Real) {
+	m := number.NewInt64(-1)
+	for _, a := range x {
+		if isZero(a.String()) {
+			continue
+		}
+		n := a.Mul(m)
+		nn := n.Mul(m)
+		if n.String() != negate(a.String()) {
+			algFail++
+			log.Printf("algcheck: %v: a*-1 != -a: a %v, a*-1 %v", s, a, n)
+		} else if nn.String() != a.String() {
+			algFail++
+			log.Printf("algcheck: %v: -(-a) != a: a %v, -(-a) %v", s, a, nn)
+		}
+	}
+
+	switch op {
+	case "add":
+		a, b := x[0], x[1]
+		ab := a.Add(b)
+		ba := b.Add(a)
+		if ab.String() != ba.String() {
+			algFail++
+			log.Printf("algcheck: %v: a+b != b+a: a %v, b %v, a+b %v, b+a %v", s, a, b, ab, ba)
+		}
+	case "subtract":
+		a, b := x[0], x[1]
+		ab := a.Sub(b)
+		ba := b.Sub(a)
+		switch {
+		case isZero(ab.String()) || isZero(ba.String()):
+			// -(b-a) would be -0, so the zeros are checked for +0
+			// instead
+			if ab.String() != ba.String() || strings.HasPrefix(ab.String(), "-") {
+				algFail++
+				log.Printf("algcheck: %v: a-b and b-a are not both +0: a %v, b %v, a-b %v, b-a %v", s, a, b, ab, ba)
+			}
+		case ab.String() != negate(ba.String()):
+			algFail++
+			log.Printf("algcheck: %v: a-b != -(b-a): a %v, b %v, a-b %v, b-a %v", s, a, b, ab, ba)
+		}
+	}
+}
+
+// negate returns the string form of a number with its sign flipped. Working
+// on the string keeps the negation exact.
+func negate(s string) string {
+	if strings.HasPrefix(s, "-") {
+		return s[1:]
+	}
+	return "-" + s
+}
+
+// isZero reports whether the string form of a number is a zero of any sign
+// or exponent.
+func isZero(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if i := strings.IndexAny(s, "eE"); i != -1 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "0.") == ""
+}
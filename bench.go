@@ -0,0 +1,108 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// benchStats are the total time and count of an operation.
+type benchStats struct {
+	total time.Duration
+	count int
+}
+
+var bench = make(map[string]*benchStats)
+
+// benchOp adds one timed run of op.
+func benchOp(op string, d time.Duration) {
+	if bench[op] == nil {
+		bench[op] = &benchStats{}
+	}
+	bench[op].total += d
+	bench[op].count++
+}
+
+// nsPerOp returns the mean time of each operation in nanoseconds.
+func nsPerOp() map[string]float64 {
+	ret := make(map[string]float64)
+	for k, v := range bench {
+		ret[k] = float64(v.total.Nanoseconds()) / float64(v.count)
+	}
+	return ret
+}
+
+// logBench writes the mean time of each operation to stdout, one
+// "op ns/op" pair per line. The output can be saved and used as a
+// -bench-baseline.
+func logBench() {
+	ns := nsPerOp()
+	for _, k := range slices.Sorted(maps.Keys(ns)) {
+		fmt.Printf("%v %.1f\n", k, ns[k])
+	}
+}
+
+// readBaseline reads a file written by logBench.
+func readBaseline(file string) map[string]float64 {
+	f, err := os.Open(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	ret := make(map[string]float64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			log.Fatalf("invalid baseline: %v", scanner.Text())
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			log.Fatalf("invalid baseline: %v: %v", scanner.Text(), err)
+		}
+		ret[fields[0]] = v
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	return ret
+}
+
+// compareBaseline logs the change in mean time of each operation from the
+// baseline, and reports whether any operation is slower by more than
+// -bench-fail-pct percent.
+func compareBaseline(file string) bool {
+	base := readBaseline(file)
+	ns := nsPerOp()
+
+	var regressed bool
+	for _, k := range slices.Sorted(maps.Keys(ns)) {
+		b, ok := base[k]
+		if !ok || b == 0 {
+			log.Printf("bench: %v: %.1f ns/op, not in baseline", k, ns[k])
+			continue
+		}
+		pct := (ns[k] - b) / b * 100
+		if *fBenchFailPct != 0 && pct > *fBenchFailPct {
+			regressed = true
+			log.Printf("bench: %v: %.1f ns/op, baseline %.1f ns/op, %+.1f%%, regressed", k, ns[k], b, pct)
+			continue
+		}
+		log.Printf("bench: %v: %.1f ns/op, baseline %.1f ns/op, %+.1f%%", k, ns[k], b, pct)
+	}
+	return regressed
+}
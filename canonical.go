@@ -0,0 +1,84 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/djfritz/number"
+)
+
+var (
+	canonCount int
+	canonFail  int
+)
+
+// checkCanonical checks that every result is in canonical form. The number
+// package has no Canonical operation to compare against, so the form is
+// checked on the string: see canonical. A result that formats canonically
+// but parses back to a different string is also reported.
+func checkCanonical(c Context, z []*number.Real) {
+	for _, v := range z {
+		s := v.String()
+		if isNaN(s) {
+			continue
+		}
+		canonCount++
+
+		if reason := canonical(s); reason != "" {
+			canonFail++
+			log.Printf("check-canonical: %v: result %v: %v", c, s, reason)
+			continue
+		}
+		y, err := number.ParseReal(s, uint(len(s))*2)
+		if err != nil {
+			canonFail++
+			log.Printf("check-canonical: %v: parsing result %v: %v", c, s, err)
+			continue
+		}
+		if y.String() != s {
+			canonFail++
+			log.Printf("check-canonical: %v: result %v formats as %v when parsed again", c, s, y)
+		}
+	}
+}
+
+// canonical returns why the string form of a finite number is not canonical,
+// or the empty string if it is. A canonical form has an optional minus sign,
+// no plus sign, no leading zeros in the integer part other than a single zero before a
+// point, at least one digit either side of a point, and an exponent, if any,
+// with a sign and no leading zeros.
+func canonical(s string) string {
+	if strings.HasPrefix(s, "+") {
+		return "plus sign"
+	}
+	s = strings.TrimPrefix(s, "-")
+
+	if i := strings.IndexAny(s, "eE"); i != -1 {
+		e := s[i+1:]
+		s = s[:i]
+		if len(e) < 2 || (e[0] != '+' && e[0] != '-') {
+			return "exponent without a sign"
+		}
+		if e[1] == '0' && len(e) > 2 {
+			return "leading zeros in the exponent"
+		}
+		if strings.Trim(e[1:], "0123456789") != "" {
+			return "invalid exponent"
+		}
+	}
+
+	ip, fp, point := strings.Cut(s, ".")
+	switch {
+	case ip == "" || strings.Trim(ip, "0123456789") != "":
+		return "invalid integer part"
+	case point && (fp == "" || strings.Trim(fp, "0123456789") != ""):
+		return "invalid fraction"
+	case len(ip) > 1 && ip[0] == '0':
+		return "leading zeros in the coefficient"
+	}
+	return ""
+}
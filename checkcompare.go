@@ -0,0 +1,45 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/djfritz/number"
+)
+
+var (
+	cmpCount int
+	cmpFail  int
+)
+
+// checkCompare verifies that compare(a, b) agrees with the sign of a - b for
+// the operands of a two operand test, whatever the operation.
+func checkCompare(c Context, x []*number.Real) {
+	if len(x) != 2 {
+		return
+	}
+	cmpCount++
+
+	a, b := x[0], x[1]
+	d := a.Sub(b)
+	if a.Compare(b) != sign(d.String()) {
+		cmpFail++
+		log.Printf("check-compare: %v: a %v, b %v, compare(a, b) %v, a-b %v", c, a, b, a.Compare(b), d)
+	}
+}
+
+// sign returns -1, 0, or 1 for the string form of a negative number, a zero,
+// or a positive number.
+func sign(s string) int {
+	switch {
+	case isZero(s):
+		return 0
+	case strings.HasPrefix(s, "-"):
+		return -1
+	}
+	return 1
+}
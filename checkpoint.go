@@ -0,0 +1,171 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+)
+
+// checkpointInterval is how many tests run between checkpoints.
+const checkpointInterval = 1000
+
+// checkpoint is the progress of a run, as written by -checkpoint and read by
+// -resume. Only the main counts and group counts are kept; the counts of the
+// optional checks start again from zero on resume.
+type checkpoint struct {
+	Done    int    // number of files completed, in the order given
+	File    string // file in progress, empty between files
+	Line    int    // last line completed in File
+	Tests   int
+	Success int
+	Fail    int
+	Skipped int
+	XFail   int
+	XPass   int
+	Groups  map[string]checkpointGroup
+}
+
+type checkpointGroup struct {
+	Tests int
+	Fail  int
+}
+
+var (
+	resumed     checkpoint // progress to skip, from -resume
+	doneFiles   int        // files completed in this run, or before it
+	lastSaved   int        // testCount at the last checkpoint
+	interrupted chan os.Signal
+)
+
+// startCheckpoint reads the checkpoint to resume from, restoring the counts,
+// and arranges for an interrupt to write a checkpoint before exiting.
+func startCheckpoint() {
+	if *fResume != "" {
+		b, err := os.ReadFile(*fResume)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(b, &resumed); err != nil {
+			log.Fatalf("reading checkpoint: %v: %v", *fResume, err)
+		}
+		testCount, success, fail, skipped = resumed.Tests, resumed.Success, resumed.Fail, resumed.Skipped
+		xfail, xpass = resumed.XFail, resumed.XPass
+		for k, v := range resumed.Groups {
+			groups[k] = &groupStats{v.Tests, v.Fail}
+		}
+		doneFiles = resumed.Done
+		lastSaved = testCount
+		if resumed.File == "" {
+			log.Printf("resuming after %v tests, %v files completed", testCount, resumed.Done)
+		} else {
+			log.Printf("resuming after %v tests, at %v:%v", testCount, resumed.File, resumed.Line)
+		}
+	}
+
+	if *fCheckpoint != "" {
+		interrupted = make(chan os.Signal, 1)
+		signal.Notify(interrupted, os.Interrupt)
+	}
+}
+
+// fileDone reports whether the i'th file was completed before the run
+// resumed. The run must be resumed with the same files, in the same order.
+func fileDone(i int, f string) bool {
+	if i == resumed.Done && resumed.File != "" && f != resumed.File {
+		log.Fatalf("resuming: expected %v, got %v", resumed.File, f)
+	}
+	return i < resumed.Done
+}
+
+// resumeSkip reports whether line s of the current file is a test that ran
+// before the run resumed. Directives and comments before the resume point
+// are still processed, so the context is the same as when the tests ran.
+func resumeSkip(s string) bool {
+	if doneFiles != resumed.Done || file != resumed.File || line > resumed.Line {
+		return false
+	}
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasPrefix(s, "--") || isDirective(s) {
+		return false
+	}
+	pragma = pragmas{}
+	return true
+}
+
+// checkpointTick writes a checkpoint every checkpointInterval tests, and
+// writes one and exits if the run was interrupted.
+func checkpointTick() {
+	if *fCheckpoint == "" {
+		return
+	}
+	select {
+	case <-interrupted:
+		saveCheckpoint(file, line)
+		stopSQL()
+		log.Printf("interrupted at %v:%v, progress saved to %v", file, line, *fCheckpoint)
+		os.Exit(1)
+	default:
+	}
+	if testCount-lastSaved >= checkpointInterval {
+		saveCheckpoint(file, line)
+	}
+}
+
+// checkpointFile records that the current file is complete. No file is in
+// progress until the next one starts.
+func checkpointFile() {
+	doneFiles++
+	if *fCheckpoint != "" {
+		saveCheckpoint("", 0)
+	}
+}
+
+// saveCheckpoint writes the progress of the run, up to line l of file f, to
+// -checkpoint. It is written to a temporary file that is then renamed, so an
+// interrupted write leaves the previous checkpoint intact.
+func saveCheckpoint(f string, l int) {
+	c := checkpoint{
+		Done:    doneFiles,
+		File:    f,
+		Line:    l,
+		Tests:   testCount,
+		Success: success,
+		Fail:    fail,
+		Skipped: skipped,
+		XFail:   xfail,
+		XPass:   xpass,
+		Groups:  make(map[string]checkpointGroup),
+	}
+	for k, v := range groups {
+		c.Groups[k] = checkpointGroup{v.tests, v.fail}
+	}
+
+	b, err := json.MarshalIndent(c, "", "\t")
+	if err != nil {
+		log.Fatal(err)
+	}
+	t, err := os.CreateTemp(filepath.Dir(*fCheckpoint), filepath.Base(*fCheckpoint)+".*")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := t.Write(b); err != nil {
+		log.Fatal(err)
+	}
+	if err := t.Sync(); err != nil {
+		log.Fatal(err)
+	}
+	if err := t.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Rename(t.Name(), *fCheckpoint); err != nil {
+		log.Fatal(err)
+	}
+	lastSaved = testCount
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+// compareFiles reports tests, matched by name, whose expected value or
+// conditions differ between two test files. No operations are run.
+func compareFiles(files []string) {
+	if len(files) != 2 {
+		log.Fatalf("-compare-files requires exactly two files, got %v", len(files))
+	}
+
+	a, aOrder := readTests(files[0])
+	b, bOrder := readTests(files[1])
+
+	var differ, missing int
+	for _, name := range aOrder {
+		ta := a[name]
+		tb, ok := b[name]
+		if !ok {
+			missing++
+			fmt.Printf("%v: only in %v\n", name, files[0])
+			continue
+		}
+		if slices.EqualFunc(ta.expected, tb.expected, sameExpected) && sameConditions(ta.conditions, tb.conditions) {
+			continue
+		}
+		differ++
+		fmt.Printf("%v: %v %v, %v %v\n", name, ta.expected, ta.conditions, tb.expected, tb.conditions)
+	}
+	for _, name := range bOrder {
+		if _, ok := a[name]; !ok {
+			missing++
+			fmt.Printf("%v: only in %v\n", name, files[1])
+		}
+	}
+
+	log.Printf("%v tests in %v, %v tests in %v. %v differ, %v unmatched", len(a), files[0], len(b), files[1], differ, missing)
+}
+
+// readTests reads all tests in a file, keyed by name. Directives and
+// comments are ignored. The names are also returned in file order.
+func readTests(file string) (map[string]testLine, []string) {
+	f, err := os.Open(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	tests := make(map[string]testLine)
+	var order []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		s := strings.TrimSpace(strings.ToLower(scanner.Text()))
+		if s == "" || strings.HasPrefix(s, "--") || strings.Contains(strings.Fields(s)[0], ":") {
+			continue
+		}
+		t, err := parseTest(s)
+		if err != nil {
+			log.Fatalf("invalid input: %v: %v", s, err)
+		}
+		if _, ok := tests[t.name]; !ok {
+			order = append(order, t.name)
+		}
+		tests[t.name] = t
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	return tests, order
+}
+
+// sameExpected compares two expected values. With -ignore-format they are
+// compared by value, so 1.0 and 1.00 are the same. Values that are not
+// numbers, such as "?", are always compared as written.
+func sameExpected(x, y string) bool {
+	if x == y {
+		return true
+	}
+	if !*fIgnoreFormat {
+		return false
+	}
+	xn, xc, xe, err := splitForm(x)
+	if err != nil {
+		return false
+	}
+	yn, yc, ye, err := splitForm(y)
+	if err != nil {
+		return false
+	}
+	if xc == "0" && yc == "0" {
+		return xn == yn
+	}
+	xc, xe = trimZeros(xc, xe)
+	yc, ye = trimZeros(yc, ye)
+	return xn == yn && xc == yc && xe == ye
+}
+
+// trimZeros removes trailing zeros from a coefficient, adjusting the
+// exponent to keep the same value.
+func trimZeros(coeff string, exp int) (string, int) {
+	t := strings.TrimRight(coeff, "0")
+	return t, exp + len(coeff) - len(t)
+}
+
+// sameConditions reports whether two lists hold the same conditions, in any
+// order.
+func sameConditions(x, y []string) bool {
+	x = slices.Clone(x)
+	y = slices.Clone(y)
+	slices.Sort(x)
+	slices.Sort(y)
+	return slices.Equal(slices.Compact(x), slices.Compact(y))
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/djfritz/number"
+)
+
+// conditions are the condition names used by the test files, lowercased.
+var conditions = []string{
+	"clamped",
+	"division_by_zero",
+	"division_impossible",
+	"division_undefined",
+	"inexact",
+	"insufficient_storage",
+	"invalid_context",
+	"invalid_operation",
+	"lost_digits",
+	"overflow",
+	"rounded",
+	"subnormal",
+	"underflow",
+}
+
+// asserted is the sorted set of conditions checked by each test, as set by
+// -assert-conditions. When empty, conditions are not checked at all.
+var asserted []string
+
+// parseAsserted sets the asserted conditions from a comma separated list of
+// condition names. The special name "all" asserts every condition.
+func parseAsserted(s string) {
+	for _, v := range strings.Split(strings.ToLower(s), ",") {
+		v = strings.TrimSpace(v)
+		switch {
+		case v == "":
+		case v == "all":
+			asserted = append(asserted, conditions...)
+		case slices.Contains(conditions, v):
+			asserted = append(asserted, v)
+		default:
+			log.Fatalf("invalid condition: %v", v)
+		}
+	}
+	slices.Sort(asserted)
+	asserted = slices.Compact(asserted)
+}
+
+// assertedConditions returns the sorted subset of the given conditions that
+// are being asserted. Anything after a "--" comment is ignored.
+func assertedConditions(c []string) []string {
+	var ret []string
+	for _, v := range c {
+		if strings.HasPrefix(v, "--") {
+			break
+		}
+		if slices.Contains(asserted, v) {
+			ret = append(ret, v)
+		}
+	}
+	slices.Sort(ret)
+	return slices.Compact(ret)
+}
+
+// deriveConditions returns the conditions raised by applying op to the
+// operands, given the results z. The number package does not report
+// conditions, so the harness derives lost_digits from the operands, and
+// inexact and rounded by repeating the operation at a wider precision and
+// comparing it to z. Rounding that discards only zeros is not always
+// detected.
+func deriveConditions(op string, operands []string, z []*number.Real) []string {
+	var c []string
+	if !extended && slices.ContainsFunc(operands, lostDigits) {
+		c = append(c, "lost_digits")
+	}
+
+	wp := 2 * precision
+	for _, v := range operands {
+		wp += 2 * uint(len(v))
+	}
+
+	w, err := computeAt(op, operands, wp)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var inexact, rounded bool
+	for i := range w {
+		_, coeff, _, err := splitForm(w[i].String())
+		if err != nil {
+			log.Fatalf("parsing: %v: %v", w[i], err)
+		}
+		if z[i].Compare(w[i]) != 0 {
+			inexact = true
+		}
+		if uint(len(coeff)) > precision {
+			rounded = true
+		}
+	}
+
+	if inexact {
+		c = append(c, "inexact", "rounded")
+	} else if rounded {
+		c = append(c, "rounded")
+	}
+	return c
+}
+
+// lostDigits reports whether rounding the operand s to the context precision
+// discards non-zero digits.
+func lostDigits(s string) bool {
+	_, coeff, _, err := splitForm(s)
+	if err != nil {
+		log.Fatalf("parsing: %v: %v", s, err)
+	}
+	if uint(len(coeff)) <= precision {
+		return false
+	}
+	return strings.Trim(coeff[precision:], "0") != ""
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+	"strings"
+)
+
+// defaults are directive lines applied at the start of every file, before
+// any directives in the file itself.
+var defaults []string
+
+// parseDefaults parses a comma separated list of directive=value pairs, such
+// as "precision=34,rounding=half_even".
+func parseDefaults(s string) {
+	if s == "" {
+		return
+	}
+	for _, v := range strings.Split(s, ",") {
+		k, val, ok := strings.Cut(v, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !ok {
+			log.Fatalf("invalid default: %v", v)
+		}
+		switch k {
+		case "precision", "rounding", "extended":
+		default:
+			log.Fatalf("invalid default directive: %v", k)
+		}
+		defaults = append(defaults, k+": "+strings.ToLower(strings.TrimSpace(val)))
+	}
+}
+
+// applyDefaults sets the context from the defaults.
+func applyDefaults() {
+	for _, v := range defaults {
+		process(v)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+	"strings"
+)
+
+// failKinds counts failed comparisons by what differs.
+var failKinds = make(map[string]int)
+
+// classifyFailure counts a failed comparison as one where the coefficient
+// differs, the exponent differs, or both. Each result is compared with its
+// expected value, or the first alternative, and the first that differs
+// decides.
+func classifyFailure(cf *CompareFailure) {
+	got := strings.Fields(cf.Got)
+	want := strings.Fields(cf.Want)
+	for i := range min(len(got), len(want)) {
+		k := failKind(got[i], strings.Split(want[i], "|")[0])
+		if k != "" {
+			failKinds[k]++
+			return
+		}
+	}
+	failKinds["other"]++
+}
+
+// failKind returns which of the coefficient and exponent differ between
+// the string forms of two numbers, or the empty string if neither does.
+// Numbers that can't be split, such as NaNs, differ in "other" ways.
+func failKind(got, want string) string {
+	gn, gc, ge, err := splitForm(got)
+	if err != nil {
+		return "other"
+	}
+	wn, wc, we, err := splitForm(want)
+	if err != nil {
+		return "other"
+	}
+	// trailing zeros only move the exponent, so 1.2 and 1.20 differ only in
+	// the exponent
+	gt, _ := trimZeros(gc, ge)
+	wt, _ := trimZeros(wc, we)
+	coeff := gn != wn || gt != wt
+	switch {
+	case coeff && ge != we:
+		return "both"
+	case coeff:
+		return "coefficient"
+	case ge != we:
+		return "exponent"
+	}
+	return ""
+}
+
+// logFailKinds logs the counts from classifyFailure.
+func logFailKinds() {
+	log.Printf("failures: %v coefficient differs, %v exponent differs, %v both, %v other", failKinds["coefficient"], failKinds["exponent"], failKinds["both"], failKinds["other"])
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context locates a test in the corpus and records the context it was run
+// under.
+type Context struct {
+	File      string
+	Line      int
+	Group     string // group label, empty if none
+	Name      string
+	Op        string
+	Test      string // the test line as read
+	Precision uint
+	Mode      int
+}
+
+func (c Context) String() string {
+	return fmt.Sprintf("%v:%v: %v", c.File, c.Line, c.Test)
+}
+
+// ParseError is returned when a test line, operand, or expected value cannot
+// be parsed.
+type ParseError struct {
+	Context
+	Input string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v: parsing: %v: %v", e.Context, e.Input, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// CompareFailure is returned when a result does not match the expected
+// value.
+type CompareFailure struct {
+	Context
+	Got  string
+	Want string
+}
+
+func (e *CompareFailure) Error() string {
+	return fmt.Sprintf("failed test: %v, %v != %v, precision: %v, rounding mode: %v", e.Context, e.Got, e.Want, e.Precision, e.Mode)
+}
+
+// ConditionMismatch is returned when the result matches but the asserted
+// conditions do not.
+type ConditionMismatch struct {
+	Context
+	Got  []string
+	Want []string
+}
+
+func (e *ConditionMismatch) Error() string {
+	return fmt.Sprintf("failed test: %v, conditions [%v] != [%v], precision: %v, rounding mode: %v", e.Context, strings.Join(e.Got, " "), strings.Join(e.Want, " "), e.Precision, e.Mode)
+}
+
+// UnsupportedOp is returned when a test uses an operation the harness does
+// not support.
+type UnsupportedOp struct {
+	Context
+	Op string
+}
+
+func (e *UnsupportedOp) Error() string {
+	return fmt.Sprintf("skipping test: %v: unsupported operation %v", e.Context, e.Op)
+}
+
+// Skip is returned when a test is not run, such as for an unsupported
+// rounding mode or an operand the test marks as invalid.
+type Skip struct {
+	Context
+	Reason string
+}
+
+func (e *Skip) Error() string {
+	return fmt.Sprintf("skipping test: %v: %v. Precision: %v. Rounding mode: %v", e.Context, e.Reason, e.Precision, e.Mode)
+}
+
+// Panic is returned, with -nopanic, when an operation panics.
+type Panic struct {
+	Context
+	Operands []string
+	Value    any
+	Stack    []byte
+}
+
+func (e *Panic) Error() string {
+	return fmt.Sprintf("panic: %v, operands [%v]: %v\n%s", e.Context, strings.Join(e.Operands, " "), e.Value, e.Stack)
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/djfritz/number"
+)
+
+// opMode is an operation run under a rounding mode.
+type opMode struct {
+	op   string
+	mode int
+}
+
+// modeFail counts failures by operation and rounding mode.
+var modeFail = make(map[opMode]int)
+
+// modeNames are the rounding modes the harness runs, in the order they are
+// reported.
+var modeNames = []struct {
+	mode int
+	name string
+}{
+	{number.ModeNearestEven, "half_even"},
+	{number.ModeNearest, "half_up"},
+	{number.ModeZero, "zero"},
+}
+
+// modeName returns the name of a rounding mode, as written in a rounding
+// directive.
+func modeName(mode int) string {
+	for _, m := range modeNames {
+		if m.mode == mode {
+			return m.name
+		}
+	}
+	return fmt.Sprint(mode)
+}
+
+// logFailModes logs a table of failures with a row for each operation that
+// failed and a column for each rounding mode.
+func logFailModes() {
+	ops := make(map[string]bool)
+	for k := range modeFail {
+		ops[k.op] = true
+	}
+
+	width := len("op")
+	for k := range ops {
+		width = max(width, len(k))
+	}
+
+	h := fmt.Sprintf("%-*v", width, "op")
+	for _, m := range modeNames {
+		h += fmt.Sprintf(" %9v", m.name)
+	}
+	log.Print(strings.TrimRight(h, " "))
+	for _, op := range slices.Sorted(maps.Keys(ops)) {
+		r := fmt.Sprintf("%-*v", width, op)
+		for _, m := range modeNames {
+			r += fmt.Sprintf(" %9v", modeFail[opMode{op, m.mode}])
+		}
+		log.Print(r)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/djfritz/number"
+)
+
+var (
+	fastCount   int
+	fastFail    int
+	fastTime    time.Duration // integer operands
+	generalTime time.Duration // the same operands written with a fraction
+)
+
+// verifyFastPath checks an add or multiply of integer operands, which the
+// number package may take a fast path for, against the same operation with
+// each operand written with a fractional digit (12 as 12.0), which cannot
+// take it. The results must be numerically equal. Both are timed.
+func verifyFastPath(c Context, op string, operands []string, wp uint) {
+	if op != "add" && op != "multiply" {
+		return
+	}
+
+	general := make([]string, len(operands))
+	for i, v := range operands {
+		neg, coeff, exp, err := splitForm(v)
+		if err != nil || exp < 0 || uint(exp) > precision {
+			return
+		}
+		general[i] = coeff + strings.Repeat("0", exp) + ".0"
+		if neg {
+			general[i] = "-" + general[i]
+		}
+	}
+	fastCount++
+
+	// each general operand has one more digit to fit
+	gwp := wp
+	if extended {
+		gwp += uint(len(operands))
+	}
+
+	start := time.Now()
+	z := computeFastPath(op, operands, wp)
+	fastTime += time.Since(start)
+
+	start = time.Now()
+	w := computeFastPath(op, general, gwp)
+	generalTime += time.Since(start)
+
+	if z.Compare(w) != 0 {
+		fastFail++
+		log.Printf("fastpath: %v: integer operands %v, general operands %v", c, z, w)
+	}
+}
+
+// computeFastPath applies op to the operands as runTest does.
+func computeFastPath(op string, operands []string, wp uint) *number.Real {
+	x := make([]*number.Real, len(operands))
+	for i, v := range operands {
+		var err error
+		x[i], err = number.ParseReal(v, uint(len(v))*2)
+		if err != nil {
+			log.Fatalf("parsing: %v: %v", v, err)
+		}
+		x[i].SetMode(mode)
+		x[i].SetPrecision(wp)
+	}
+	z, _ := compute(op, x)
+	z[0].SetPrecision(precision)
+	return z[0]
+}
+
+// logFastPath logs the -verify-fastpath summary.
+func logFastPath() {
+	log.Printf("fast path: %v integer tests, %v mismatches", fastCount, fastFail)
+	if fastTime != 0 {
+		log.Printf("fast path: integer %v, general %v, speedup %.2fx", fastTime, generalTime, float64(generalTime)/float64(fastTime))
+	}
+}
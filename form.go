@@ -0,0 +1,46 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// splitForm splits the string form of a finite number into its sign,
+// coefficient, and exponent, such that the value is coefficient * 10^exponent.
+// Leading zeros are removed from the coefficient, leaving "0" for zero.
+func splitForm(s string) (neg bool, coeff string, exp int, err error) {
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+
+	if i := strings.IndexAny(s, "eE"); i != -1 {
+		exp, err = strconv.Atoi(s[i+1:])
+		if err != nil {
+			return false, "", 0, fmt.Errorf("invalid exponent: %v", s)
+		}
+		s = s[:i]
+	}
+
+	if i := strings.Index(s, "."); i != -1 {
+		exp -= len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return false, "", 0, fmt.Errorf("invalid coefficient: %v", s)
+	}
+
+	coeff = strings.TrimLeft(s, "0")
+	if coeff == "" {
+		coeff = "0"
+	}
+	return neg, coeff, exp, nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+	"os"
+	"runtime"
+)
+
+// memoryInterval is how many tests run between heap checks. Reading the
+// memory statistics stops the world, so it isn't done for every test.
+const memoryInterval = 100
+
+// checkMemory aborts the run, after logging the summary and recording the
+// results so far, when the heap exceeds -max-memory megabytes.
+func checkMemory() {
+	if *fMaxMemory == 0 || testCount%memoryInterval != 0 {
+		return
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	if m.HeapAlloc <= *fMaxMemory<<20 {
+		return
+	}
+
+	log.Printf("%v:%v: heap is %.1f MB, over the %v MB limit; aborting after %v tests", file, line, float64(m.HeapAlloc)/(1<<20), *fMaxMemory, testCount)
+	stopProfile()
+	stopSQL()
+	summary()
+	os.Exit(1)
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+// minimize reduces a test file to a minimal set of lines that still fail the
+// same way, and writes them to stdout. If -minimize-test names a test, the
+// lines must still make that test fail; otherwise every test that fails in
+// the whole file must still fail. Tests are removed by delta debugging, then
+// directives are removed one at a time where they are not needed.
+func minimize(files []string) {
+	if len(files) != 1 {
+		log.Fatalf("-minimize requires exactly one file, got %v", len(files))
+	}
+	file = files[0]
+
+	f, err := os.Open(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	f.Close()
+
+	// pragma comments are kept with the test they apply to
+	var tests, directives, pending []int
+	attached := make(map[int][]int)
+	for i, v := range lines {
+		s := strings.TrimSpace(strings.ToLower(v))
+		switch {
+		case strings.HasPrefix(s, "--") && strings.HasPrefix(strings.TrimSpace(s[2:]), "@"):
+			pending = append(pending, i)
+		case s == "" || strings.HasPrefix(s, "--"):
+		case isDirective(s):
+			directives = append(directives, i)
+		default:
+			tests = append(tests, i)
+			attached[i], pending = pending, nil
+		}
+	}
+
+	want := failures(lines, slices.Concat(directives, tests), attached)
+	target := strings.ToLower(*fMinimizeTest)
+	if target != "" && !slices.Contains(want, target) {
+		log.Fatalf("test %v does not fail in %v", *fMinimizeTest, file)
+	}
+	if len(want) == 0 {
+		log.Fatalf("no tests fail in %v", file)
+	}
+	runs := 0
+	fails := func(keep []int) bool {
+		runs++
+		got := failures(lines, keep, attached)
+		if target != "" {
+			return slices.Contains(got, target)
+		}
+		return slices.Equal(got, want)
+	}
+
+	tests = ddmin(tests, func(t []int) bool {
+		return fails(slices.Concat(directives, t))
+	})
+	for i := len(directives) - 1; i >= 0; i-- {
+		d := slices.Delete(slices.Clone(directives), i, i+1)
+		if fails(slices.Concat(d, tests)) {
+			directives = d
+		}
+	}
+
+	keep := slices.Concat(directives, tests)
+	for _, i := range tests {
+		keep = append(keep, attached[i]...)
+	}
+	slices.Sort(keep)
+	for _, i := range keep {
+		fmt.Println(lines[i])
+	}
+	log.Printf("minimized %v lines to %v in %v runs", len(lines), len(keep), runs)
+}
+
+// ddmin returns a subset of items, minimal in that removing any one chunk of
+// it makes fails false, for which fails is true. fails must be true for all
+// of items.
+func ddmin(items []int, fails func([]int) bool) []int {
+	n := 2
+	for len(items) >= 2 {
+		size := (len(items) + n - 1) / n
+		var chunks [][]int
+		for c := range slices.Chunk(items, size) {
+			chunks = append(chunks, c)
+		}
+
+		reduced := false
+		for _, c := range chunks {
+			if fails(c) {
+				items, n, reduced = c, 2, true
+				break
+			}
+		}
+		if !reduced && len(chunks) > 2 {
+			for i := range chunks {
+				c := slices.Concat(slices.Concat(chunks[:i]...), slices.Concat(chunks[i+1:]...))
+				if fails(c) {
+					items, n, reduced = c, max(n-1, 2), true
+					break
+				}
+			}
+		}
+		if !reduced {
+			if n >= len(items) {
+				break
+			}
+			n = min(2*n, len(items))
+		}
+	}
+	return items
+}
+
+// failures runs the given lines, in file order, in a fresh context and
+// returns the names of the tests that fail, other than those expected to
+// fail. attached holds the pragma comments for each test. The tests are not
+// counted, and the optional checks are not run.
+func failures(lines []string, keep []int, attached map[int][]int) []string {
+	keep = slices.Sorted(slices.Values(keep))
+	precision, mode, extended, skip = 0, 0, false, false
+	applyDefaults()
+	rerun = true
+	defer func() { rerun = false }()
+
+	var ret []string
+	for _, i := range keep {
+		line = i + 1
+		s := strings.TrimSpace(strings.ToLower(lines[i]))
+		if isDirective(s) {
+			process(s)
+			continue
+		}
+		pragma = pragmas{}
+		for _, p := range attached[i] {
+			processComment(strings.TrimSpace(strings.ToLower(lines[p])))
+		}
+		c, err := runTest(s)
+		var cf *CompareFailure
+		var cm *ConditionMismatch
+		if (errors.As(err, &cf) || errors.As(err, &cm)) && !pragma.expectFail {
+			ret = append(ret, c.Name)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"runtime/debug"
+)
+
+// panics is the number of tests whose operation panicked, with -nopanic.
+var panics int
+
+// recoverOp runs f, which computes the operation of a test. With -nopanic a
+// panic in f is recovered and returned as a *Panic, so the run can continue.
+func recoverOp(c Context, operands []string, f func()) (err error) {
+	if !*fNoPanic {
+		f()
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &Panic{c, operands, r, debug.Stack()}
+		}
+	}()
+	f()
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/djfritz/number"
+)
+
+var (
+	notationCount int
+	notationFail  int
+)
+
+// checkNotation checks that every result, written in scientific and in
+// engineering notation, parses back to the same value. The number package
+// has no ToSci or ToEng, so both forms are made from String here; the check
+// is on ParseReal reading them alike.
+func checkNotation(c Context, z []*number.Real) {
+	for _, v := range z {
+		s := v.String()
+		if isNaN(s) {
+			continue
+		}
+		notationCount++
+
+		sci, eng, err := notations(s)
+		if err != nil {
+			notationFail++
+			log.Printf("check-notation: %v: result %v: %v", c, s, err)
+			continue
+		}
+		x, err := number.ParseReal(sci, uint(len(sci))*2)
+		if err != nil {
+			notationFail++
+			log.Printf("check-notation: %v: parsing %v: %v", c, sci, err)
+			continue
+		}
+		y, err := number.ParseReal(eng, uint(len(eng))*2)
+		if err != nil {
+			notationFail++
+			log.Printf("check-notation: %v: parsing %v: %v", c, eng, err)
+			continue
+		}
+		if x.Compare(y) != 0 || x.Compare(v) != 0 {
+			notationFail++
+			log.Printf("check-notation: %v: result %v, scientific %v reads as %v, engineering %v reads as %v", c, s, sci, x, eng, y)
+		}
+	}
+}
+
+// notations returns the string form of a finite number in scientific and
+// engineering notation, as the to-scientific-string and
+// to-engineering-string operations of the specification would write it.
+func notations(s string) (sci, eng string, err error) {
+	neg, coeff, exp, err := splitForm(s)
+	if err != nil {
+		return "", "", err
+	}
+	sign := ""
+	if neg {
+		sign = "-"
+	}
+
+	adj := exp + len(coeff) - 1
+	if exp <= 0 && adj >= -6 {
+		p := plain(coeff, exp)
+		return sign + p, sign + p, nil
+	}
+
+	sci = sign + coeff[:1]
+	if len(coeff) > 1 {
+		sci += "." + coeff[1:]
+	}
+	sci += fmt.Sprintf("E%+d", adj)
+
+	if coeff == "0" {
+		// the engineering form of a zero only moves the exponent to a
+		// multiple of three, which doesn't change the value
+		return sci, sci, nil
+	}
+
+	// the exponent is a multiple of three, with one to three digits
+	// before the point
+	e := adj - ((adj%3)+3)%3
+	n := adj - e + 1
+	if len(coeff) <= n {
+		eng = sign + coeff + strings.Repeat("0", n-len(coeff))
+	} else {
+		eng = sign + coeff[:n] + "." + coeff[n:]
+	}
+	if e != 0 {
+		eng += fmt.Sprintf("E%+d", e)
+	}
+	return sci, eng, nil
+}
+
+// plain writes coefficient * 10^exp, with exp <= 0, without an exponent.
+func plain(coeff string, exp int) string {
+	if exp == 0 {
+		return coeff
+	}
+	n := len(coeff) + exp
+	if n > 0 {
+		return coeff[:n] + "." + coeff[n:]
+	}
+	return "0." + strings.Repeat("0", -n) + coeff
+}
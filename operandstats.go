@@ -0,0 +1,123 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// digitBuckets are the upper bounds of the operand length histogram.
+var digitBuckets = []int{1, 9, 16, 34, 100}
+
+// operandStats reports what the tests in files exercise: the operations, the
+// precisions, and the length and sign of the operands. No operations are run.
+func operandStats(files []string) {
+	var tests, operands, negative, special int
+	ops := make(map[string]int)
+	precisions := make(map[uint]int)
+	lengths := make([]int, len(digitBuckets)+1)
+
+	for _, v := range files {
+		f, err := os.Open(v)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var prec uint
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			s := strings.TrimSpace(strings.ToLower(scanner.Text()))
+			if s == "" || strings.HasPrefix(s, "--") {
+				continue
+			}
+			if isDirective(s) {
+				if p, ok := strings.CutPrefix(s, "precision:"); ok {
+					x, err := strconv.ParseUint(strings.Fields(p)[0], 10, 64)
+					if err != nil {
+						log.Fatalf("parsing precision: %v, %v", err, s)
+					}
+					prec = uint(x)
+				}
+				continue
+			}
+
+			t, err := parseTest(s)
+			if err != nil {
+				log.Fatalf("invalid input: %v: %v", s, err)
+			}
+			tests++
+			ops[t.op]++
+			precisions[prec]++
+
+			for _, o := range t.operands {
+				operands++
+				if strings.HasPrefix(o, "-") {
+					negative++
+				}
+				_, coeff, _, err := splitForm(o)
+				if err != nil {
+					// NaN, Infinity, or a null operand
+					special++
+					continue
+				}
+				i, _ := slices.BinarySearch(digitBuckets, len(coeff))
+				lengths[i]++
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatal(err)
+		}
+		f.Close()
+	}
+
+	log.Printf("%v tests, %v operands. %v negative, %v special", tests, operands, percent(negative, operands), percent(special, operands))
+
+	opNames := slices.SortedFunc(maps.Keys(ops), func(a, b string) int {
+		return cmp.Or(ops[b]-ops[a], strings.Compare(a, b))
+	})
+	var s []string
+	for _, k := range opNames {
+		s = append(s, fmt.Sprintf("%v %v", k, ops[k]))
+	}
+	log.Printf("operations: %v", strings.Join(s, ", "))
+
+	s = nil
+	for _, k := range slices.Sorted(maps.Keys(precisions)) {
+		s = append(s, fmt.Sprintf("%v: %v", k, precisions[k]))
+	}
+	log.Printf("precisions: %v", strings.Join(s, ", "))
+
+	s = nil
+	lo := 1
+	for i, n := range lengths {
+		switch {
+		case i == len(digitBuckets):
+			s = append(s, fmt.Sprintf(">%v: %v", digitBuckets[i-1], n))
+		case lo == digitBuckets[i]:
+			s = append(s, fmt.Sprintf("%v: %v", lo, n))
+			lo = digitBuckets[i] + 1
+		default:
+			s = append(s, fmt.Sprintf("%v-%v: %v", lo, digitBuckets[i], n))
+			lo = digitBuckets[i] + 1
+		}
+	}
+	log.Printf("operand digits: %v", strings.Join(s, ", "))
+}
+
+// percent formats n as a percentage of total.
+func percent(n, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)/float64(total)*100)
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/djfritz/number"
+)
+
+// operation is an operation that can be tested, taking a fixed number of
+// operands and returning a fixed number of results. An inexact operation's
+// result is generally not exact at any precision.
+type operation struct {
+	operands int
+	results  int
+	fn       func(x []*number.Real) []*number.Real
+	inexact  bool
+}
+
+// operations are the supported operations, keyed by the name used in test
+// files.
+var operations = map[string]operation{
+	"abs":        unary((*number.Real).Abs),
+	"add":        binary((*number.Real).Add),
+	"compare":    binary(compare),
+	"divide":     inexact(binary((*number.Real).Div)),
+	"divmod":     {2, 2, divmod, false},
+	"exp":        inexact(unary((*number.Real).Exp)),
+	"ln":         inexact(unary((*number.Real).Ln)),
+	"max":        binary((*number.Real).Max),
+	"min":        binary((*number.Real).Min),
+	"multiply":   binary((*number.Real).Mul),
+	"power":      inexact(binary((*number.Real).Pow)),
+	"remainder":  binary((*number.Real).Remainder),
+	"squareroot": inexact(unary((*number.Real).Sqrt)),
+	"subtract":   binary((*number.Real).Sub),
+}
+
+// aliases map other names used for operations by some corpora to the names
+// in operations.
+var aliases = map[string]string{
+	"div":  "divide",
+	"mul":  "multiply",
+	"rem":  "remainder",
+	"sqrt": "squareroot",
+	"sub":  "subtract",
+}
+
+func unary(f func(x *number.Real) *number.Real) operation {
+	return operation{1, 1, func(x []*number.Real) []*number.Real {
+		return []*number.Real{f(x[0])}
+	}, false}
+}
+
+func binary(f func(x, y *number.Real) *number.Real) operation {
+	return operation{2, 1, func(x []*number.Real) []*number.Real {
+		return []*number.Real{f(x[0], x[1])}
+	}, false}
+}
+
+func inexact(o operation) operation {
+	o.inexact = true
+	return o
+}
+
+func compare(x, y *number.Real) *number.Real {
+	return number.NewInt64(int64(x.Compare(y)))
+}
+
+// divmod returns the integer quotient and remainder of x/y. It is not part
+// of the decimal arithmetic specification, and exists to exercise
+// operations with more than one result.
+func divmod(x []*number.Real) []*number.Real {
+	r := x[0].Remainder(x[1])
+	q := x[0].Sub(r).Div(x[1])
+	return []*number.Real{q, r}
+}
+
+// opTime is how long the operation took in the last call to compute, for
+// -bench.
+var opTime time.Duration
+
+// compute applies op to the operands. It returns false if op is not
+// supported.
+func compute(op string, x []*number.Real) ([]*number.Real, bool) {
+	o, ok := operations[op]
+	if !ok || len(x) != o.operands {
+		return nil, false
+	}
+	start := time.Now()
+	z := o.fn(x)
+	opTime = time.Since(start)
+	return z, true
+}
+
+// computeAt parses the operands exactly and applies op at precision prec,
+// using the current rounding mode.
+func computeAt(op string, operands []string, prec uint) ([]*number.Real, error) {
+	x := make([]*number.Real, len(operands))
+	for i, v := range operands {
+		var err error
+		x[i], err = number.ParseReal(v, max(prec, uint(len(v))*2))
+		if err != nil {
+			return nil, fmt.Errorf("parsing: %v: %w", v, err)
+		}
+		x[i].SetMode(mode)
+		x[i].SetPrecision(prec)
+	}
+
+	z, ok := compute(op, x)
+	if !ok {
+		return nil, fmt.Errorf("unsupported operation: %v", op)
+	}
+	return z, nil
+}
+
+// computeOnce applies an inexact op to the exact operands, returning results
+// that round correctly to the context precision. The operation is done at
+// working precision wp, and repeated with more digits while the digits
+// beyond the context precision are too close to a rounding boundary for a
+// second rounding to be safe.
+func computeOnce(op string, operands []string, wp uint) ([]*number.Real, error) {
+	wp += 2
+	for range 4 {
+		z, err := computeAt(op, operands, wp)
+		if err != nil {
+			return nil, err
+		}
+		if tracing {
+			tracef("%v at precision %v: %v", op, wp, z)
+		}
+		if !slices.ContainsFunc(z, nearBoundary) {
+			return z, nil
+		}
+		wp *= 2
+	}
+	return computeAt(op, operands, wp)
+}
+
+// nearBoundary reports whether rounding x to the context precision could
+// differ from rounding the exact value it approximates. The last digit of x
+// is uncertain, so the digits between the context precision and it must not
+// be all zeros, all nines, or a half.
+func nearBoundary(x *number.Real) bool {
+	_, coeff, _, err := splitForm(x.String())
+	if err != nil || uint(len(coeff)) <= precision+1 {
+		return false
+	}
+	tail := coeff[precision : len(coeff)-1]
+	switch {
+	case strings.Trim(tail, "0") == "", strings.Trim(tail, "9") == "":
+		return true
+	case tail[0] == '5' && strings.Trim(tail[1:], "0") == "":
+		return true
+	case tail[0] == '4' && strings.Trim(tail[1:], "9") == "":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"errors"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// rng is the source of all randomness in a run, seeded from -seed.
+var rng *rand.Rand
+
+// seedRNG seeds rng from -seed, or from the time if -seed is 0. The seed is
+// logged so the run can be repeated.
+func seedRNG() {
+	seed := uint64(*fSeed)
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	rng = rand.New(rand.NewPCG(seed, 0))
+	if *fOrder == "shuffle" || *fSample != 1 {
+		log.Printf("seed: %v", seed)
+	}
+}
+
+// queued is a test held back to be run in a different order, along with the
+// state it would have run under.
+type queued struct {
+	line   int
+	s      string
+	group  string
+	pragma pragmas
+}
+
+var (
+	queue      []queued
+	orderCount int
+	orderDiff  int
+)
+
+// queueTest holds back a test until the next directive or the end of the
+// file, see flushTests.
+func queueTest(s string) {
+	queue = append(queue, queued{line, s, group, pragma})
+	pragma = pragmas{}
+}
+
+// flushTests runs the queued tests in the order given by -order. The tests
+// share a directive context, so any order is valid. Each test is then run
+// again, uncounted and without the optional checks, in file order and any
+// test whose outcome differs is reported as order dependent.
+func flushTests() {
+	if len(queue) == 0 {
+		return
+	}
+	l, g := line, group
+
+	run := slices.Clone(queue)
+	switch *fOrder {
+	case "reverse":
+		slices.Reverse(run)
+	case "shuffle":
+		rng.Shuffle(len(run), func(i, j int) {
+			run[i], run[j] = run[j], run[i]
+		})
+	}
+
+	got := make(map[int]string)
+	for _, v := range run {
+		line, group, pragma = v.line, v.group, v.pragma
+		got[v.line] = outcome(processTest(v.s))
+	}
+
+	rerun = true
+	for _, v := range queue {
+		line = v.line
+		_, err := runTest(v.s)
+		orderCount++
+		if o := outcome(err); o != got[v.line] {
+			orderDiff++
+			log.Printf("order dependent: %v:%v: %v: in %v order: %v, in file order: %v", file, v.line, v.s, *fOrder, got[v.line], o)
+		}
+	}
+	rerun = false
+
+	queue = queue[:0]
+	line, group, pragma = l, g, pragmas{}
+}
+
+// outcome describes how the last test run ended: the kind of error from
+// runTest, and the result if there was one. Unlike the error itself, it
+// doesn't include anything that differs between runs, such as the stack of a
+// panic.
+func outcome(err error) string {
+	var cf *CompareFailure
+	var cm *ConditionMismatch
+	var pa *Panic
+	var uo *UnsupportedOp
+	var sk *Skip
+	kind := "error"
+	switch {
+	case err == nil:
+		kind = "pass"
+	case errors.As(err, &cf):
+		kind = "fail"
+	case errors.As(err, &cm):
+		kind = "condition mismatch"
+	case errors.As(err, &pa):
+		kind = "panic"
+	case errors.As(err, &uo), errors.As(err, &sk):
+		kind = "skip"
+	}
+	if result == "" {
+		return kind
+	}
+	return kind + ": " + result
+}
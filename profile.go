@@ -0,0 +1,46 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"runtime/pprof"
+)
+
+// startProfile starts writing a CPU profile to -cpuprofile, if set.
+func startProfile() {
+	if *fCPUProfile == "" {
+		return
+	}
+	f, err := os.Create(*fCPUProfile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// stopProfile stops the CPU profile, if any, and flushes it.
+func stopProfile() {
+	if *fCPUProfile != "" {
+		pprof.StopCPUProfile()
+	}
+}
+
+// labelOp runs f, which computes op. With -label-ops, profile samples taken
+// while f runs carry an "op" label, so "pprof -tags" breaks the profile down
+// by operation.
+func labelOp(op string, f func()) {
+	if !*fLabelOps {
+		f()
+		return
+	}
+	pprof.Do(context.Background(), pprof.Labels("op", op), func(context.Context) {
+		f()
+	})
+}
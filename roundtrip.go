@@ -0,0 +1,46 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+
+	"github.com/djfritz/number"
+)
+
+var (
+	rtCount int
+	rtFail  int
+)
+
+// roundTrip checks that parsing s, formatting it with String, and parsing the
+// result again gives a value that is numerically and formally identical to
+// the first.
+func roundTrip(c Context, s string) {
+	if s == "#" || s == "?" || isNaN(s) {
+		return
+	}
+	rtCount++
+
+	x, err := number.ParseReal(s, uint(len(s))*2)
+	if err != nil {
+		rtFail++
+		log.Printf("roundtrip: %v: parsing: %v: %v", c, s, err)
+		return
+	}
+
+	f := x.String()
+	y, err := number.ParseReal(f, uint(max(len(s), len(f)))*2)
+	if err != nil {
+		rtFail++
+		log.Printf("roundtrip: %v: parsing: %v -> %v: %v", c, s, f, err)
+		return
+	}
+
+	if y.String() != f || x.Compare(y) != 0 {
+		rtFail++
+		log.Printf("roundtrip: %v: %v -> %v -> %v", c, s, f, y)
+	}
+}
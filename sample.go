@@ -0,0 +1,30 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+)
+
+// unsampled is the number of tests not run because of -sample.
+var unsampled int
+
+// sampled reports whether the next test should run under -sample.
+func sampled() bool {
+	return *fSample == 1 || rng.Float64() < *fSample
+}
+
+// logSample logs how much of the corpus was sampled, and the number of
+// failures in the whole corpus estimated from the sampled pass rate.
+func logSample() {
+	total := testCount + unsampled
+	if testCount == 0 {
+		log.Printf("sampled 0 of %v tests", total)
+		return
+	}
+	rate := float64(success) / float64(testCount)
+	est := float64(fail) / float64(testCount) * float64(total)
+	log.Printf("sampled %v of %v tests (%v). pass rate %.1f%%, estimated %.0f failures in all tests", testCount, total, percent(testCount, total), rate*100, est)
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// severities are the valid severities, most severe first. Operations not
+// given a severity are unrated.
+var severities = []string{"high", "medium", "low", "unrated"}
+
+// severityWeight is what a failure of each severity counts for in the
+// weighted total, so a few high severity failures outweigh many low ones.
+var severityWeight = map[string]int{
+	"high":    100,
+	"medium":  10,
+	"low":     1,
+	"unrated": 1,
+}
+
+var (
+	severity = make(map[string]string) // op -> severity
+	opFail   = make(map[string]int)    // op -> failures
+)
+
+// parseSeverity parses a comma separated list of op=severity pairs. An op
+// may be given by any of its aliases.
+func parseSeverity(s string) {
+	for _, v := range strings.Split(strings.ToLower(s), ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		op, sev, ok := strings.Cut(v, "=")
+		if !ok || !slices.Contains(severities[:3], sev) {
+			log.Fatalf("invalid severity: %v", v)
+		}
+		if a, ok := aliases[op]; ok {
+			op = a
+		}
+		if _, ok := operations[op]; !ok {
+			log.Fatalf("invalid severity: %v: unknown operation %v", v, op)
+		}
+		severity[op] = sev
+	}
+}
+
+// logSeverity logs the failure counts for each severity, and for each
+// operation within it, then the raw and weighted totals.
+func logSeverity() {
+	bySev := make(map[string][]string)
+	count := make(map[string]int)
+	for _, op := range slices.Sorted(maps.Keys(opFail)) {
+		sev, ok := severity[op]
+		if !ok {
+			sev = "unrated"
+		}
+		bySev[sev] = append(bySev[sev], op)
+		count[sev] += opFail[op]
+	}
+
+	for _, sev := range severities {
+		if count[sev] == 0 {
+			continue
+		}
+		var ops []string
+		for _, op := range bySev[sev] {
+			ops = append(ops, fmt.Sprintf("%v %v", op, opFail[op]))
+		}
+		log.Printf("%v severity failures: %v (%v)", sev, count[sev], strings.Join(ops, ", "))
+	}
+
+	var raw, weighted int
+	for _, sev := range severities {
+		raw += count[sev]
+		weighted += count[sev] * severityWeight[sev]
+	}
+	log.Printf("%v failures, weighted %v (high %v, medium %v, low and unrated %v each)", raw, weighted, severityWeight["high"], severityWeight["medium"], severityWeight["low"])
+}
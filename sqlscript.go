@@ -0,0 +1,130 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// result is the string form of the results of the last test run, or empty if
+// it was not computed.
+var result string
+
+// sqlBatch is how many rows are written in each transaction.
+const sqlBatch = 1000
+
+var (
+	sqlFile  *os.File
+	sqlRows  bytes.Buffer // INSERT statements not yet written
+	sqlCount int          // rows in sqlRows
+	sqlRun   string       // identifies the run in every row
+)
+
+// sqlSchema creates the results table.
+const sqlSchema = `CREATE TABLE IF NOT EXISTS results (
+	run       TEXT,
+	file      TEXT,
+	line      INTEGER,
+	grp       TEXT,
+	name      TEXT,
+	op        TEXT,
+	operands  TEXT,
+	expected  TEXT,
+	actual    TEXT,
+	status    TEXT,
+	precision INTEGER,
+	mode      TEXT
+);
+CREATE INDEX IF NOT EXISTS results_run ON results (run);
+`
+
+// startSQL opens -sql-script for appending, and writes the schema. The run
+// is identified by the time it started, so rows from a number of runs can be
+// ordered and compared.
+//
+// There is no SQLite driver in the standard library, so rows are written as
+// an SQL script, which is loaded into a database with the sqlite3 shell:
+//
+//	sqlite3 results.db < results.sql
+//	SELECT * FROM results WHERE op = 'divide' AND status = 'fail'
+//	    AND run IN (SELECT DISTINCT run FROM results ORDER BY run DESC LIMIT 10);
+func startSQL() {
+	if *fSQLScript == "" {
+		return
+	}
+	var err error
+	sqlFile, err = os.OpenFile(*fSQLScript, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sqlRun = time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := sqlFile.WriteString(sqlSchema); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// recordSQL adds a row for the test just run, writing the batch when it is
+// full.
+func recordSQL(c Context, status string) {
+	var operands, expected string
+	if t, err := parseTest(c.Test); err == nil {
+		operands = strings.Join(t.operands, " ")
+		expected = strings.Join(t.expected, " ")
+	}
+	actual := "NULL"
+	if result != "" {
+		actual = sqlQuote(result)
+	}
+	grp := "NULL"
+	if c.Group != "" {
+		grp = sqlQuote(c.Group)
+	}
+	fmt.Fprintf(&sqlRows, "INSERT INTO results VALUES (%v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v);\n",
+		sqlQuote(sqlRun), sqlQuote(c.File), c.Line, grp, sqlQuote(c.Name), sqlQuote(c.Op),
+		sqlQuote(operands), sqlQuote(expected), actual, sqlQuote(status), c.Precision, sqlQuote(modeName(c.Mode)))
+	sqlCount++
+	if sqlCount == sqlBatch {
+		flushSQL()
+	}
+}
+
+// flushSQL writes the rows not yet written, in one transaction. The
+// transaction is written whole, so a run that ends with a fatal error loses
+// at most the rows of one batch, and never leaves a transaction open.
+func flushSQL() {
+	if sqlCount == 0 {
+		return
+	}
+	var b bytes.Buffer
+	b.WriteString("BEGIN;\n")
+	sqlRows.WriteTo(&b)
+	b.WriteString("COMMIT;\n")
+	if _, err := sqlFile.Write(b.Bytes()); err != nil {
+		log.Fatal(err)
+	}
+	sqlCount = 0
+}
+
+// stopSQL writes the rows not yet written and closes -sql-script. It is
+// called when the run is over, or stopped early with the summary logged.
+func stopSQL() {
+	if *fSQLScript == "" {
+		return
+	}
+	flushSQL()
+	if err := sqlFile.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// sqlQuote returns s as an SQL string literal.
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+
+	"github.com/djfritz/number"
+)
+
+// resultStats are the largest coefficient and exponent seen in any result,
+// and the tests that produced them.
+var resultStats struct {
+	digits     int
+	digitsTest string
+	exp        int
+	expTest    string
+}
+
+// updateStats updates resultStats with the results of a test.
+func updateStats(c Context, z []*number.Real) {
+	for _, v := range z {
+		_, coeff, exp, err := splitForm(v.String())
+		if err != nil {
+			continue
+		}
+		if len(coeff) > resultStats.digits {
+			resultStats.digits = len(coeff)
+			resultStats.digitsTest = c.Name
+		}
+		if exp < 0 {
+			exp = -exp
+		}
+		if exp > resultStats.exp || resultStats.expTest == "" {
+			resultStats.exp = exp
+			resultStats.expTest = c.Name
+		}
+	}
+}
+
+// logStats logs the -stats summary.
+func logStats() {
+	log.Printf("largest coefficient: %v digits (%v)", resultStats.digits, resultStats.digitsTest)
+	log.Printf("largest exponent: %v (%v)", resultStats.exp, resultStats.expTest)
+}
@@ -6,10 +6,14 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"maps"
+	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -17,56 +21,285 @@ import (
 )
 
 var (
-	fV = flag.Bool("v", false, "verbose mode")
+	fV              = flag.Bool("v", false, "verbose mode")
+	fAlgCheck       = flag.Bool("algcheck", false, "verify algebraic identities (a+b == b+a, a-b == -(b-a)) for add and subtract tests, and -(-a) == a for every operand")
+	fCheckCanonical = flag.Bool("check-canonical", false, "check that every result is formatted in canonical form")
+	fCheckNotation  = flag.Bool("check-notation", false, "check that every result reads back as the same value in scientific and engineering notation")
+	fCheckTrans     = flag.Bool("check-transcendental", false, "check that ln(exp(a)) and exp(ln(a)) are a for the operands of exp and ln tests")
+	fTransUlps      = flag.Uint("transcendental-ulps", 1, "with -check-transcendental, the difference allowed in units of the last place")
+	fCheckCompare   = flag.Bool("check-compare", false, "check that compare(a, b) agrees with the sign of a-b for the operands of every two operand test")
+	fAssert         = flag.String("assert-conditions", "", "comma separated list of conditions to assert, or \"all\". Empty asserts none")
+	fArrow          = flag.String("arrow", "->", "the token that separates the operands of a test from the expected results")
+	fTolerant       = flag.Bool("tolerant", false, "warn about and skip unknown directives, rather than treating them as tests")
+	fDefaults       = flag.String("defaults", "", "comma separated directive=value list (precision, rounding, or extended) applied at the start of every file")
+	fTrace          = flag.String("trace", "", "log each step the harness, not the number package, takes for the named test")
+	fBench          = flag.Bool("bench", false, "time each operation and write the mean ns/op of each to stdout")
+	fBenchBaseline  = flag.String("bench-baseline", "", "with -bench, compare the mean ns/op of each operation against a file saved from a previous -bench run")
+	fBenchFailPct   = flag.Float64("bench-fail-pct", 0, "with -bench-baseline, exit non-zero if any operation is slower than the baseline by more than this percent. 0 disables")
+	fOrder          = flag.String("order", "file", "order to run the tests between directives: file, reverse, or shuffle. Other than file, each test is also run in file order and differences reported")
+	fPrefix         = flag.String("prefix", "", "comma separated list of test name prefixes. Only tests whose names start with one are run")
+	fSample         = flag.Float64("sample", 1, "run only this fraction, between 0 and 1, of the tests, chosen at random")
+	fSeed           = flag.Int64("seed", 0, "seed for -order shuffle and -sample. 0 uses the time")
+	fCPUProfile     = flag.String("cpuprofile", "", "write a CPU profile to this file")
+	fLabelOps       = flag.Bool("label-ops", false, "label CPU profile samples with the operation being run")
+	fCheckpoint     = flag.String("checkpoint", "", "write the progress of the run to this file periodically and on interrupt")
+	fResume         = flag.String("resume", "", "continue the run saved in this checkpoint file, skipping the tests already run")
+	fSQLScript      = flag.String("sql-script", "", "append SQL that records the result of every test to this file, to be loaded into a database with the sqlite3 shell")
+	fNoPanic        = flag.Bool("nopanic", false, "recover from a panic in an operation, report it as a failure, and continue")
+	fMaxMemory      = flag.Uint64("max-memory", 0, "abort the run when the heap exceeds this many megabytes. 0 disables")
+	fCompareFiles   = flag.Bool("compare-files", false, "compare the expected values of two test files instead of running tests")
+	fOperandStats   = flag.Bool("operand-stats", false, "report the operations, precisions, and operands the tests exercise instead of running them")
+	fMinimize       = flag.Bool("minimize", false, "reduce a test file to the fewest lines that fail the same way, and write them to stdout")
+	fMinimizeTest   = flag.String("minimize-test", "", "with -minimize, keep only what is needed for the named test to fail")
+	fIgnoreFormat   = flag.Bool("ignore-format", false, "with -compare-files, compare expected values by value rather than as written")
+	fOnlyConditions = flag.Bool("only-conditions", false, "ignore results and check only conditions. Asserts all conditions unless -assert-conditions is set")
+	fWarnExponent   = flag.Int("warn-exponent", 0, "warn when the adjusted exponent of a result exceeds this magnitude. 0 disables")
+	fOutputPrec     = flag.Uint("output-precision", 0, "also log failing (or, with -v, all) results computed to this many digits, for diagnosis only")
+	fRoundTrip      = flag.Bool("roundtrip", false, "check that every operand, expected value, and result survives String and ParseReal unchanged")
+	fDiffExponent   = flag.Bool("diff-against-exponent", false, "classify each failed result by whether its coefficient, exponent, or both differ from the expected value")
+	fFailModes      = flag.Bool("fail-modes", false, "report failures in a table by operation and rounding mode")
+	fSeverity       = flag.String("severity", "", "comma separated op=severity list (high, medium, or low) used to group and weight failures in the summary")
+	fVerifyFastPath = flag.Bool("verify-fastpath", false, "check and time add and multiply of integer operands against the same operands written with a fraction")
+	fStats          = flag.Bool("stats", false, "report the largest coefficient and exponent of any result")
+	fVerifyRounding = flag.Bool("verify-rounding", false, "check inexact results against the operation repeated at a much higher precision and rounded once")
+	fAuditTies      = flag.Bool("audit-ties", false, "check half even rounding of generated add, multiply, and divide results that are exact ties")
+	fNormalizeNaN   = flag.Bool("normalize-nan", false, "treat any NaN result as equal to any expected NaN, ignoring payload and sign. By default NaNs are compared exactly")
 )
 
 var (
+	file      string // current file
+	line      int    // current line number
 	precision uint
 	mode      int
+	extended  bool
 	skip      bool
 	testCount int
 	success   int
 	fail      int
 	skipped   int
+	algFail   int
+	xfail     int // expected failures
+	xpass     int // unexpected passes
+	expWarn   int
+	unknown   int  // unknown directives, with -tolerant
+	rerun     bool // the test is being run again, uncounted, for -order or -minimize
+)
+
+// groupStats are the counts for tests under a group label.
+type groupStats struct {
+	tests int
+	fail  int
+}
+
+// prefixes are the test name prefixes from -prefix.
+var prefixes []string
+
+var (
+	group  string // current group label, empty if none
+	groups = make(map[string]*groupStats)
 )
 
 func main() {
 	flag.Parse()
 
+	if *fOnlyConditions && *fAssert == "" {
+		parseAsserted("all")
+	} else {
+		parseAsserted(*fAssert)
+	}
+	parseSeverity(*fSeverity)
+	parseDefaults(*fDefaults)
+	if *fPrefix != "" {
+		prefixes = strings.Split(strings.ToLower(*fPrefix), ",")
+	}
+
+	switch *fOrder {
+	case "file", "reverse", "shuffle":
+	default:
+		log.Fatalf("invalid order: %v", *fOrder)
+	}
+	if *fSample <= 0 || *fSample > 1 {
+		log.Fatalf("invalid sample: %v", *fSample)
+	}
+	seedRNG()
+
 	files := flag.Args()
 
-	for _, v := range files {
+	if *fCompareFiles {
+		compareFiles(files)
+		return
+	}
+
+	if *fOperandStats {
+		operandStats(files)
+		return
+	}
+
+	if *fMinimize {
+		minimize(files)
+		return
+	}
+
+	if *fCheckpoint != "" && *fOrder != "file" {
+		log.Fatalf("-checkpoint requires -order file")
+	}
+	startCheckpoint()
+	startSQL()
+	startProfile()
+
+	for i, v := range files {
+		if fileDone(i, v) {
+			continue
+		}
 		f, err := os.Open(v)
 		if err != nil {
 			log.Fatal(err)
 		}
+		file = v
+		line = 0
+		group = ""
+		pragma = pragmas{}
+		applyDefaults()
 
 		scanner := bufio.NewScanner(f)
 		for scanner.Scan() {
-			process(strings.ToLower(scanner.Text()))
+			line++
+			s := strings.ToLower(scanner.Text())
+			if resumeSkip(s) {
+				continue
+			}
+			process(s)
+			checkpointTick()
 		}
+		flushTests()
 
 		if err := scanner.Err(); err != nil {
 			log.Fatal(err)
 		}
 		f.Close()
+		checkpointFile()
+	}
+
+	if *fAuditTies {
+		auditTies()
+	}
+
+	stopProfile()
+	stopSQL()
+	summary()
+
+	if *fBench {
+		logBench()
+		if *fBenchBaseline != "" && compareBaseline(*fBenchBaseline) {
+			os.Exit(1)
+		}
 	}
+}
 
-	log.Printf("%v tests. %v successful, %v failed, %v skipped", testCount, success, fail, skipped)
+// summary logs the counts for the run.
+func summary() {
+	if *fOnlyConditions {
+		log.Printf("%v tests. %v conditions matched, %v conditions mismatched, %v skipped", testCount, success, fail, skipped)
+	} else {
+		log.Printf("%v tests. %v successful, %v failed, %v skipped", testCount, success, fail, skipped)
+	}
+	if *fSample != 1 {
+		logSample()
+	}
+	if *fNoPanic {
+		log.Printf("%v operations panicked", panics)
+	}
+	if *fTolerant {
+		log.Printf("%v unknown directives skipped", unknown)
+	}
+	if xfail != 0 || xpass != 0 {
+		log.Printf("%v expected failures, %v unexpected passes", xfail, xpass)
+	}
+	if *fAssert != "" || *fOnlyConditions {
+		if len(asserted) == 0 {
+			log.Printf("asserting no conditions")
+		} else {
+			log.Printf("asserting conditions: %v", strings.Join(asserted, ", "))
+		}
+	}
+	if *fAlgCheck {
+		log.Printf("%v algebraic identity violations", algFail)
+	}
+	if *fOrder != "file" {
+		log.Printf("%v tests run again in file order, %v order dependent", orderCount, orderDiff)
+	}
+	if *fCheckCanonical {
+		log.Printf("%v results checked, %v not canonical", canonCount, canonFail)
+	}
+	if *fCheckNotation {
+		log.Printf("%v results checked, %v differ between notations", notationCount, notationFail)
+	}
+	if *fCheckTrans {
+		log.Printf("%v exp and ln round trips, %v beyond %v ulps", transCount, transFail, *fTransUlps)
+	}
+	if *fCheckCompare {
+		log.Printf("%v operand pairs compared, %v disagree with subtract", cmpCount, cmpFail)
+	}
+	if *fStats {
+		logStats()
+	}
+	if *fAuditTies {
+		log.Printf("%v ties audited, %v rounded to odd", tieCount, tieFail)
+	}
+	if *fVerifyRounding {
+		log.Printf("%v inexact results checked, %v misrounded", roundedCount, misrounded)
+	}
+	if *fVerifyFastPath {
+		logFastPath()
+	}
+	if *fRoundTrip {
+		log.Printf("%v of %v values failed to round trip", rtFail, rtCount)
+	}
+	if *fWarnExponent != 0 {
+		log.Printf("%v results with adjusted exponent beyond %v", expWarn, *fWarnExponent)
+	}
+	if *fSeverity != "" {
+		logSeverity()
+	}
+	if *fDiffExponent {
+		logFailKinds()
+	}
+	if *fFailModes {
+		logFailModes()
+	}
+	for _, k := range slices.Sorted(maps.Keys(groups)) {
+		log.Printf("group %v: %v tests, %v failed", k, groups[k].tests, groups[k].fail)
+	}
 }
 
 func process(s string) {
+	// this also removes the CR from files with CRLF line endings
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return
 	} else if strings.HasPrefix(s, "--") {
 		// comment
+		processComment(s)
 		return
-	} else if strings.HasPrefix(s, "version") {
+	} else if !isDirective(s) && !hasPrefix(s) {
+		// excluded by -prefix, before sampling so it isn't counted as
+		// unsampled
+		pragma = pragmas{}
 		return
-	} else if strings.HasPrefix(s, "extended") {
-		// doesn't apply to us
+	} else if !isDirective(s) && !sampled() {
+		unsampled++
+		pragma = pragmas{}
+		return
+	} else if *fOrder != "file" && !isDirective(s) {
+		queueTest(s)
+		return
+	}
+
+	flushTests()
+	if strings.HasPrefix(s, "version") {
 		return
+	} else if strings.HasPrefix(s, "extended") {
+		processExtended(s)
 	} else if strings.HasPrefix(s, "maxexponent") {
 		// doesn't apply to us
 		return
@@ -77,6 +310,9 @@ func process(s string) {
 		processPrecision(s)
 	} else if strings.HasPrefix(s, "rounding") {
 		processRounding(s)
+	} else if *fTolerant && isDirective(s) {
+		unknown++
+		log.Printf("%v:%v: unknown directive: %v", file, line, s)
 	} else {
 		processTest(s)
 	}
@@ -89,6 +325,9 @@ func processPrecision(s string) {
 	if err != nil {
 		log.Fatalf("parsing precision: %v, %v", err, s)
 	}
+	if x == 0 {
+		log.Fatalf("invalid precision: %v", s)
+	}
 	precision = uint(x)
 
 	if *fV {
@@ -96,6 +335,27 @@ func processPrecision(s string) {
 	}
 }
 
+// processExtended sets whether the extended arithmetic is in use. In the
+// subset arithmetic (extended: 0), operands are rounded to the context
+// precision before the operation. In the extended arithmetic they are used
+// exactly.
+func processExtended(s string) {
+	s = strings.TrimSpace(strings.TrimPrefix(s, "extended:"))
+	fields := strings.Fields(s)
+	switch fields[0] {
+	case "0":
+		extended = false
+	case "1":
+		extended = true
+	default:
+		log.Fatalf("invalid extended: %v", s)
+	}
+
+	if *fV {
+		fmt.Println("setting extended:", s)
+	}
+}
+
 func processRounding(s string) {
 	s = strings.TrimSpace(strings.TrimPrefix(s, "rounding:"))
 	skip = false
@@ -117,127 +377,593 @@ func processRounding(s string) {
 	}
 }
 
-func processTest(s string) {
-	testCount++
-	if skip {
-		skipped++
-		if *fV {
-			log.Printf("skipping test: %v. Precision: %v. Rounding mode: %v", s, precision, mode)
-		}
+// processComment looks for a group label or a pragma in a comment. A group
+// label has the form "-- [group: name]", and applies to all following tests in
+// the file until the next label. An empty name ends the group.
+func processComment(s string) {
+	s = strings.TrimSpace(strings.TrimPrefix(s, "--"))
+	if strings.HasPrefix(s, "@") {
+		processPragma(s)
+		return
+	}
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return
+	}
+	k, v, ok := strings.Cut(s[1:len(s)-1], ":")
+	if !ok || strings.TrimSpace(k) != "group" {
 		return
 	}
+	group = strings.TrimSpace(v)
+
+	if *fV {
+		fmt.Println("setting group:", group)
+	}
+}
+
+// testLine is a single test, as parsed from a line in a test file.
+type testLine struct {
+	name       string
+	op         string
+	operands   []string
+	expected   []string
+	conditions []string
+	skip       string // reason from a "-- @skip" marker, if any
+}
+
+// parseTest parses a test line of the form
+//
+//	name op operand... -> expected... condition... -- comment
+//
+// The -> separator can be changed with -arrow. A test whose comment is
+// "@skip reason" is parsed but not run.
+// Operation aliases are replaced by the operation name. The number of
+// expected values is the number of results of the operation, or one if the
+// operation is not supported. An expected value may list acceptable
+// alternatives separated by "|". Quotes around operands and expected values
+// are removed.
+func parseTest(s string) (testLine, error) {
+	var t testLine
 
 	fields := strings.Fields(s)
+	arrow := slices.Index(fields, *fArrow)
+	if arrow == -1 {
+		return t, fmt.Errorf("missing %v", *fArrow)
+	}
 
-	var lo, ro, ez *number.Real
-	var e string
-	var err error
-	if len(fields) < 5 {
-		log.Fatalf("invalid input: %v", s)
+	t.name = fields[0]
+	t.op = fields[1]
+	if op, ok := aliases[t.op]; ok {
+		t.op = op
 	}
 
-	name := fields[0]
-	op := fields[1]
-	l := strings.Trim(fields[2], "'")
+	n := 1
+	if o, ok := operations[t.op]; ok {
+		n = o.results
+	}
+	if arrow < 3 || len(fields) < arrow+1+n {
+		return t, fmt.Errorf("too few fields")
+	}
 
-	if l == "#" {
+	for _, v := range fields[2:arrow] {
+		t.operands = append(t.operands, strings.Trim(v, "'"))
+	}
+	for _, v := range fields[arrow+1 : arrow+1+n] {
+		alt := strings.Split(v, "|")
+		for i := range alt {
+			alt[i] = strings.Trim(alt[i], "'")
+		}
+		t.expected = append(t.expected, strings.Join(alt, "|"))
+	}
+	for i, v := range fields[arrow+1+n:] {
+		if strings.HasPrefix(v, "--") {
+			t.skip = authoredSkip(fields[arrow+1+n+i:])
+			break
+		}
+		t.conditions = append(t.conditions, v)
+	}
+	return t, nil
+}
+
+// authoredSkip returns the skip reason from a trailing comment of the form
+// "-- @skip reason", or the empty string if the comment is not a skip
+// marker.
+func authoredSkip(comment []string) string {
+	c := strings.Fields(strings.TrimPrefix(strings.Join(comment, " "), "--"))
+	if len(c) == 0 || c[0] != "@skip" {
+		return ""
+	}
+	if len(c) == 1 {
+		return "authored-skip"
+	}
+	return "authored-skip: " + strings.Join(c[1:], " ")
+}
+
+// pragmas are set by "-- @name" comments and apply to the next test only.
+// The pragmas are:
+//
+//	@expectfail	the test is expected to fail
+type pragmas struct {
+	expectFail bool
+}
+
+var pragma pragmas
+
+// processPragma sets a pragma for the next test. Unknown pragmas are
+// ignored.
+func processPragma(s string) {
+	fields := strings.Fields(strings.TrimPrefix(s, "@"))
+	if len(fields) == 0 {
+		return
+	}
+	switch fields[0] {
+	case "expectfail":
+		pragma.expectFail = true
+	default:
+		if *fV {
+			log.Printf("%v:%v: ignoring unknown pragma: %v", file, line, s)
+		}
+	}
+}
+
+// hasPrefix reports whether the name of a test starts with one of the
+// -prefix prefixes, or -prefix is not set.
+func hasPrefix(s string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	name := strings.Fields(s)[0]
+	for _, v := range prefixes {
+		if strings.HasPrefix(name, strings.TrimSpace(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDirective reports whether a line is a directive rather than a test.
+func isDirective(s string) bool {
+	f := strings.Fields(s)
+	return len(f) != 0 && strings.Contains(f[0], ":")
+}
+
+// processTest runs and counts a test, returning the error from runTest.
+func processTest(s string) error {
+	testCount++
+	checkMemory()
+	if group != "" {
+		if groups[group] == nil {
+			groups[group] = &groupStats{}
+		}
+		groups[group].tests++
+	}
+
+	p := pragma
+	pragma = pragmas{}
+
+	c, err := runTest(s)
+
+	var pe *ParseError
+	var cf *CompareFailure
+	var cm *ConditionMismatch
+	var uo *UnsupportedOp
+	var sk *Skip
+	var pa *Panic
+	var status string
+	switch {
+	case err == nil && p.expectFail:
+		xpass++
+		status = "xpass"
+		log.Printf("unexpected pass: %v:%v: %v", file, line, s)
+	case err == nil:
+		success++
+		status = "pass"
+	case errors.As(err, &pe):
+		log.Fatal(err)
+	case (errors.As(err, &cf) || errors.As(err, &cm)) && p.expectFail:
+		xfail++
+		status = "xfail"
+		if *fV {
+			log.Printf("expected failure: %v", err)
+		}
+	case errors.As(err, &cf), errors.As(err, &cm):
+		fail++
+		status = "fail"
+		if group != "" {
+			groups[group].fail++
+		}
+		opFail[c.Op]++
+		modeFail[opMode{c.Op, c.Mode}]++
+		if *fDiffExponent && errors.As(err, &cf) {
+			classifyFailure(cf)
+		}
+		log.Print(err)
+	case errors.As(err, &pa):
+		fail++
+		panics++
+		status = "panic"
+		if group != "" {
+			groups[group].fail++
+		}
+		opFail[c.Op]++
+		modeFail[opMode{c.Op, c.Mode}]++
+		log.Print(err)
+	case errors.As(err, &uo), errors.As(err, &sk):
 		skipped++
+		status = "skip"
 		if *fV {
-			log.Printf("skipping test: %v. Precision: %v. Rounding mode: %v", s, precision, mode)
+			log.Print(err)
 		}
-		return
+	default:
+		log.Fatal(err)
 	}
 
-	lo, err = number.ParseReal(l, uint(len(l))*2)
+	if *fSQLScript != "" {
+		recordSQL(c, status)
+	}
+	return err
+}
+
+// runTest runs a single test line, returning the context it ran under. The
+// error is nil if the test passes, or one of the error types in errors.go
+// describing why it did not.
+func runTest(s string) (Context, error) {
+	c := Context{
+		File:      file,
+		Line:      line,
+		Group:     group,
+		Test:      s,
+		Precision: precision,
+		Mode:      mode,
+	}
+
+	tracing = false
+	result = ""
+
+	if skip {
+		return c, &Skip{c, "unsupported rounding mode"}
+	}
+
+	t, err := parseTest(s)
 	if err != nil {
-		log.Fatalf("parsing: %v: %v", l, err)
-	}
-	lo.SetMode(mode)
-	lo.SetPrecision(precision)
-
-	if len(fields) == 5 || fields[3] == "->" {
-		// single operand
-		e = strings.Trim(fields[4], "'")
-		if e == "?" {
-			skipped++
-			if *fV {
-				log.Printf("skipping test: %v. Precision: %v. Rounding mode: %v", s, precision, mode)
+		return c, &ParseError{c, s, err}
+	}
+	c.Name = t.name
+	c.Op = t.op
+
+	tracing = *fTrace != "" && t.name == strings.ToLower(*fTrace)
+	if tracing {
+		tracef("%v: precision %v, rounding mode %v, extended %v", c, precision, mode, extended)
+	}
+
+	if t.skip != "" {
+		return c, &Skip{c, t.skip}
+	}
+
+	o, ok := operations[t.op]
+	if !ok {
+		return c, &UnsupportedOp{c, t.op}
+	}
+	if slices.Contains(t.operands, "#") || slices.Contains(t.expected, "?") {
+		return c, &Skip{c, "invalid operand or undefined result"}
+	}
+	if len(t.operands) != o.operands {
+		return c, &ParseError{c, s, fmt.Errorf("%v takes %v operands", t.op, o.operands)}
+	}
+
+	if *fV {
+		fmt.Printf("test %v, op %v, operands %v, expected %v", t.name, t.op, t.operands, t.expected)
+	}
+
+	// in the extended arithmetic operands are used exactly: the operation
+	// is done at a working precision wide enough to hold them, and only the
+	// result is rounded to the context precision and mode. The subset
+	// arithmetic rounds the operands first, in the same mode. The mode is
+	// set before the precision, so any rounding of an operand uses it.
+	wp := precision
+	operands := t.operands
+	if extended {
+		operands = foldSticky(t.op, t.operands)
+		wp, err = workingPrecision(operands)
+		if err != nil {
+			return c, &ParseError{c, s, err}
+		}
+	}
+	if tracing {
+		tracef("operands %v, working precision %v", operands, wp)
+	}
+
+	x := make([]*number.Real, len(operands))
+	for i, v := range operands {
+		x[i], err = number.ParseReal(v, uint(len(v))*2)
+		if err != nil {
+			return c, &ParseError{c, v, err}
+		}
+		x[i].SetMode(mode)
+		x[i].SetPrecision(wp)
+	}
+	if tracing {
+		tracef("operands at working precision %v", x)
+	}
+
+	// expected values are compared by their string form. NaNs are kept as
+	// written.
+	ez := make([]string, len(t.expected))
+	for i, v := range t.expected {
+		alt := strings.Split(v, "|")
+		for j, a := range alt {
+			if isNaN(a) {
+				continue
 			}
-			return
+			e, err := number.ParseReal(a, uint(len(a))*2)
+			if err != nil {
+				return c, &ParseError{c, a, err}
+			}
+			alt[j] = e.String()
 		}
-		if *fV {
-			fmt.Printf("test %v, op %v, l %v, expected %v", name, op, l, e)
+		ez[i] = strings.Join(alt, "|")
+	}
+
+	var z []*number.Real
+	perr := recoverOp(c, t.operands, func() {
+		labelOp(t.op, func() {
+			if extended && o.inexact {
+				z, err = computeOnce(t.op, operands, wp)
+			} else {
+				z, _ = compute(t.op, x)
+			}
+		})
+	})
+	if perr != nil {
+		return c, perr
+	}
+	if err != nil {
+		return c, &ParseError{c, s, err}
+	}
+	if *fBench && !rerun {
+		// only the operation is timed. For an inexact operation in the
+		// extended arithmetic, that is the last, and most precise, of the
+		// attempts computeOnce made.
+		benchOp(t.op, opTime)
+	}
+	if tracing {
+		tracef("result before rounding %v", z)
+	}
+	for _, v := range z {
+		v.SetPrecision(precision)
+	}
+	if tracing {
+		tracef("result %v, expected %v", z, ez)
+	}
+	result = join(z)
+
+	if *fV {
+		log.Printf("result after rounding: %v", join(z))
+	}
+
+	if !rerun {
+		runChecks(c, t, x, z, wp)
+	}
+
+	if !*fOnlyConditions && !slices.EqualFunc(z, ez, sameResult) {
+		if *fOutputPrec != 0 {
+			logOutputPrecision(c, t)
 		}
-	} else {
-		// dual operand
-		e = strings.Trim(fields[5], "'")
-		if e == "?" {
-			skipped++
-			if *fV {
-				log.Printf("skipping test: %v. Precision: %v. Rounding mode: %v", s, precision, mode)
+		return c, &CompareFailure{c, join(z), strings.Join(ez, " ")}
+	}
+	if *fOutputPrec != 0 && *fV {
+		logOutputPrecision(c, t)
+	}
+
+	if len(asserted) != 0 {
+		want := assertedConditions(t.conditions)
+		got := assertedConditions(deriveConditions(t.op, t.operands, z))
+		if !slices.Equal(want, got) {
+			return c, &ConditionMismatch{c, got, want}
+		}
+	}
+
+	return c, nil
+}
+
+// runChecks runs the optional checks enabled by flags on the result z of
+// test t, computed from the operands x at working precision wp.
+func runChecks(c Context, t testLine, x, z []*number.Real, wp uint) {
+	if *fAlgCheck {
+		algCheck(c.Test, t.op, x)
+	}
+
+	if *fCheckCompare {
+		checkCompare(c, x)
+	}
+
+	if *fCheckCanonical {
+		checkCanonical(c, z)
+	}
+
+	if *fCheckNotation {
+		checkNotation(c, z)
+	}
+
+	if *fCheckTrans {
+		checkTranscendental(c, t.op, x)
+	}
+
+	if *fVerifyFastPath {
+		verifyFastPath(c, t.op, t.operands, wp)
+	}
+
+	if *fWarnExponent != 0 {
+		warnExponent(c, z)
+	}
+
+	if *fStats {
+		updateStats(c, z)
+	}
+
+	if *fVerifyRounding {
+		used := t.operands
+		if !extended {
+			used = make([]string, len(x))
+			for i, v := range x {
+				used[i] = v.String()
 			}
-			return
 		}
-		r := strings.Trim(fields[3], "'")
-		ro, err = number.ParseReal(r, uint(len(r))*2)
-		if err != nil {
-			log.Fatalf("parsing: %v: %v", r, err)
+		verifyRounding(c, t.op, used, z)
+	}
+
+	if *fRoundTrip {
+		for _, v := range t.operands {
+			roundTrip(c, v)
 		}
-		ro.SetMode(mode)
-		ro.SetPrecision(precision)
-		if *fV {
-			fmt.Printf("test %v, op %v, l %v, r %v, expected %v", name, op, l, r, e)
+		for _, v := range t.expected {
+			for _, a := range strings.Split(v, "|") {
+				roundTrip(c, a)
+			}
+		}
+		for _, v := range z {
+			roundTrip(c, v.String())
 		}
 	}
+}
 
-	ez, err = number.ParseReal(e, uint(len(e))*2)
+// logOutputPrecision logs the result of the test computed to -output-precision
+// digits, to show where a result diverges before it is rounded.
+func logOutputPrecision(c Context, t testLine) {
+	w, err := computeAt(t.op, t.operands, *fOutputPrec)
 	if err != nil {
-		log.Fatalf("parsing: %v: %v", e, err)
-	}
-
-	var z *number.Real
-	switch op {
-	case "abs":
-		z = lo.Abs()
-	case "add":
-		z = lo.Add(ro)
-	case "subtract":
-		z = lo.Sub(ro)
-	case "divide":
-		z = lo.Div(ro)
-	case "multiply":
-		z = lo.Mul(ro)
-	case "power":
-		z = lo.Pow(ro)
-	case "exp":
-		z = lo.Exp()
-	case "ln":
-		z = lo.Ln()
-	case "squareroot":
-		z = lo.Sqrt()
-	case "compare":
-		z = number.NewInt64(int64(lo.Compare(ro)))
-	case "max":
-		z = lo.Max(ro)
-	case "min":
-		z = lo.Min(ro)
-	case "remainder":
-		z = lo.Remainder(ro)
-	default:
-		if *fV {
-			log.Printf("skipping test: %v. Precision: %v. Rounding mode: %v", s, precision, mode)
-		}
+		log.Printf("%v: %v", c, err)
 		return
 	}
+	log.Printf("%v: result to %v digits: %v", c, *fOutputPrec, join(w))
+}
 
-	if *fV {
-		log.Printf("result after rounding: %v", z)
+// warnExponent warns about results whose adjusted exponent is larger in
+// magnitude than -warn-exponent. Such results often come from a runaway
+// algorithm, even when they match the expected value.
+func warnExponent(c Context, z []*number.Real) {
+	for _, v := range z {
+		_, coeff, exp, err := splitForm(v.String())
+		if err != nil {
+			// not a finite number
+			continue
+		}
+		adj := exp + len(coeff) - 1
+		if adj > *fWarnExponent || adj < -*fWarnExponent {
+			expWarn++
+			log.Printf("warning: %v: result %v has adjusted exponent %v", c, v, adj)
+		}
 	}
+}
 
-	if z.String() != ez.String() {
-		fail++
-		log.Printf("failed test: %v, %v != %v, precision: %v, rounding mode: %v", s, z, ez, precision, mode)
-	} else {
-		success++
+// guardDigits is how many digits beyond the context precision an exponent
+// span is allowed before workingPrecision stops widening for it.
+const guardDigits = 2
+
+// workingPrecision returns a precision at which the exact result of adding,
+// subtracting, or multiplying the operands can be represented, and never less
+// than the context precision. Inexact operations start from this precision,
+// see computeOnce. The span of the exponents counts for no more than the
+// context precision and guardDigits, so an add or subtract of operands
+// further apart must first be folded by foldSticky.
+func workingPrecision(operands []string) (uint, error) {
+	var digits int
+	hi, lo := math.MinInt, math.MaxInt
+	for _, v := range operands {
+		_, coeff, exp, err := splitForm(v)
+		if err != nil {
+			return 0, err
+		}
+		digits += len(coeff)
+		hi = max(hi, exp+len(coeff))
+		lo = min(lo, exp)
+	}
+	span := min(hi-lo, int(precision)+guardDigits)
+	return max(precision, uint(max(digits, span))+1), nil
+}
+
+// foldSticky returns the operands of an add or subtract with an operand that
+// lies wholly below the other's digits and the context precision and
+// guardDigits replaced by a single sticky digit, 1 or 0 with its sign, just
+// below them. Such an operand can only move the exact result off a multiple
+// of that digit's place, never across a rounding boundary, so the result
+// rounds the same. Other operands are returned unchanged.
+func foldSticky(op string, operands []string) []string {
+	if (op != "add" && op != "subtract") || len(operands) != 2 {
+		return operands
+	}
+	var neg [2]bool
+	var coeff [2]string
+	var exp, top [2]int
+	for i, v := range operands {
+		var err error
+		neg[i], coeff[i], exp[i], err = splitForm(v)
+		if err != nil {
+			return operands
+		}
+		top[i] = exp[i] + len(coeff[i])
+	}
+
+	big, small := 0, 1
+	if top[1] > top[0] {
+		big, small = 1, 0
+	}
+	if strings.Trim(coeff[big], "0") == "" {
+		// a zero has no digits for the other operand to lie below
+		return operands
+	}
+	bottom := min(exp[big], top[big]-int(precision)-guardDigits)
+	if top[small] > bottom {
+		return operands
+	}
+
+	sticky := "1"
+	if strings.Trim(coeff[small], "0") == "" {
+		sticky = "0"
+	}
+	if neg[small] {
+		sticky = "-" + sticky
+	}
+	folded := slices.Clone(operands)
+	folded[small] = fmt.Sprintf("%vE%+d", sticky, bottom-1)
+	return folded
+}
+
+// sameResult reports whether the result z matches the string form of an
+// expected value, or any of its "|" separated alternatives.
+func sameResult(z *number.Real, e string) bool {
+	for _, v := range strings.Split(e, "|") {
+		if sameValue(z, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue reports whether the result z matches the string form of a single
+// expected value.
+func sameValue(z *number.Real, e string) bool {
+	if !isNaN(e) {
+		return z.String() == e
+	}
+	zs := strings.ToLower(z.String())
+	if *fNormalizeNaN {
+		return isNaN(zs)
+	}
+	return zs == e
+}
+
+// isNaN reports whether s is a NaN, qNaN, or sNaN, with optional sign and
+// payload.
+func isNaN(s string) bool {
+	s = strings.TrimLeft(strings.ToLower(s), "+-")
+	return strings.HasPrefix(s, "nan") || strings.HasPrefix(s, "qnan") || strings.HasPrefix(s, "snan")
+}
+
+// join returns the string forms of x separated by spaces.
+func join(x []*number.Real) string {
+	s := make([]string, len(x))
+	for i, v := range x {
+		s[i] = v.String()
 	}
+	return strings.Join(s, " ")
 }
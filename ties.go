@@ -0,0 +1,115 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/djfritz/number"
+)
+
+var (
+	tieCount int
+	tieFail  int
+)
+
+// tiePrecisions are the precisions ties are audited at.
+var tiePrecisions = []uint{1, 2, 3, 5, 9, 16, 34}
+
+// auditTies runs generated add, multiply, and divide operations whose exact
+// result lies exactly half way between two representable values, and checks
+// that half even rounding picks the even one. Every operand fits in the
+// precision, so only the result is rounded.
+func auditTies() {
+	for _, p := range tiePrecisions {
+		for _, a := range tieOperands(p) {
+			n := new(big.Int)
+			n.SetString(a, 10)
+
+			// a + 0.5 = a5 * 10^-1, tie between a and a+1
+			auditTie(p, "add", a, "0.5", n, 0)
+
+			// a * 5 = N5 * 10^0 when a*5 has p+1 digits
+			m := new(big.Int).Mul(n, big.NewInt(5))
+			if len(m.String()) == int(p)+1 && m.Bit(0) == 1 {
+				auditTie(p, "multiply", a, "5", m.Div(m, big.NewInt(10)), 1)
+			}
+
+			// a / 2 = q5 * 10^-1 when a is odd and q has p digits
+			q := new(big.Int).Rsh(n, 1)
+			if n.Bit(0) == 1 && len(q.String()) == int(p) {
+				auditTie(p, "divide", a, "2", q, 0)
+			}
+		}
+	}
+}
+
+// tieOperands returns distinct integer operands of exactly p digits,
+// covering every last digit and leading digits that make the results carry.
+// Below precision 3 there are no middle digits, so the same operands come up
+// more than once and only the first is kept.
+func tieOperands(p uint) []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for _, lead := range []string{"1", "2", "4", "9"} {
+		for _, mid := range []string{"0", "9"} {
+			for last := range 10 {
+				var a string
+				if p == 1 {
+					a = fmt.Sprint(last)
+					if lead != "1" || mid != "0" || last == 0 {
+						continue
+					}
+				} else {
+					a = lead + strings.Repeat(mid, int(p)-2) + fmt.Sprint(last)
+				}
+				if seen[a] {
+					continue
+				}
+				seen[a] = true
+				ret = append(ret, a)
+			}
+		}
+	}
+	return ret
+}
+
+// auditTie applies op to a and b at precision p with half even rounding. The
+// exact result is n5 * 10^(exp-1), so the correctly rounded result is n or
+// n+1, whichever is even, times 10^exp.
+func auditTie(p uint, op, a, b string, n *big.Int, exp int) {
+	tieCount++
+
+	want := new(big.Int).Set(n)
+	if want.Bit(0) == 1 {
+		want.Add(want, big.NewInt(1))
+	}
+	ws := fmt.Sprintf("%vE%+d", want, exp)
+
+	x := make([]*number.Real, 2)
+	for i, v := range []string{a, b} {
+		var err error
+		x[i], err = number.ParseReal(v, uint(len(v))*2)
+		if err != nil {
+			log.Fatalf("parsing: %v: %v", v, err)
+		}
+		x[i].SetMode(number.ModeNearestEven)
+		x[i].SetPrecision(p)
+	}
+	z, _ := compute(op, x)
+
+	w, err := number.ParseReal(ws, uint(len(ws))*2)
+	if err != nil {
+		log.Fatalf("parsing: %v: %v", ws, err)
+	}
+
+	if z[0].Compare(w) != 0 {
+		tieFail++
+		log.Printf("tie rounded to odd: %v %v %v, precision %v: %v, want %v", op, a, b, p, z[0], ws)
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+)
+
+// tracing is set while the test named by -trace runs. The trace is of the
+// steps the harness takes, such as the working precision and the results
+// before and after rounding; the number package has no hooks to trace its
+// own steps.
+var tracing bool
+
+// tracef logs a step of the traced test. Callers check tracing first, so
+// that the arguments aren't boxed for every test when not tracing.
+func tracef(format string, args ...any) {
+	log.Printf("trace: "+format, args...)
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/djfritz/number"
+)
+
+// transGuard is the number of digits beyond the context precision that
+// exp and ln are computed with in a round trip, so that the round trip
+// itself loses less than a unit in the last place. More are added for
+// small exp operands and large ln operands, see checkTranscendental.
+const transGuard = 10
+
+var (
+	transCount int
+	transFail  int
+)
+
+// checkTranscendental checks that ln(exp(a)) is a, for the operand of an exp
+// test, and that exp(ln(a)) is a, for the operand of an ln test, to within
+// -transcendental-ulps units in the last place of a at the context
+// precision. Operands outside the domain of the inner function, or of 10000
+// or more in magnitude for exp, are not checked.
+func checkTranscendental(c Context, op string, x []*number.Real) {
+	if (op != "exp" && op != "ln") || len(x) != 1 {
+		return
+	}
+	s := x[0].String()
+	_, coeff, exp, err := splitForm(s)
+	if err != nil {
+		return
+	}
+	adj := exp + len(coeff) - 1
+	switch {
+	case op == "exp" && adj >= 4 && coeff != "0":
+		return
+	case op == "ln" && sign(s) != 1:
+		return
+	}
+
+	a, err := number.ParseReal(s, uint(len(s))*2)
+	if err != nil {
+		log.Printf("check-transcendental: %v: parsing %v: %v", c, s, err)
+		return
+	}
+	// exp(a) of a small a is 1 followed by the digits of a, and the digits
+	// of the integer part of ln(a) for a large a are lost to the fraction
+	wp := precision + transGuard
+	if op == "exp" && adj < 0 {
+		wp += uint(-adj)
+	} else if op == "ln" {
+		wp += uint(len(fmt.Sprint(max(adj, -adj))))
+	}
+	a.SetMode(mode)
+	a.SetPrecision(wp)
+	transCount++
+
+	var r *number.Real
+	var name string
+	if op == "exp" {
+		r, name = a.Exp().Ln(), "ln(exp(a))"
+	} else {
+		r, name = a.Ln().Exp(), "exp(ln(a))"
+	}
+	r.SetPrecision(precision)
+
+	// the tolerance, in units of the last place of a at the context
+	// precision
+	ts := fmt.Sprintf("%vE%v", *fTransUlps, adj-int(precision)+1)
+	tol, err := number.ParseReal(ts, uint(len(ts))*2)
+	if err != nil {
+		log.Fatalf("parsing: %v: %v", ts, err)
+	}
+	if r.Sub(a).Abs().Compare(tol) > 0 {
+		transFail++
+		log.Printf("check-transcendental: %v: a %v, %v %v, more than %v ulps", c, s, name, r, *fTransUlps)
+	}
+}
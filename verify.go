@@ -0,0 +1,48 @@
+// Copyright 2025 David Fritz. All rights reserved.
+// This software may be modified and distributed under the terms of the BSD
+// 2-clause license. See the LICENSE file for details.
+
+package main
+
+import (
+	"log"
+
+	"github.com/djfritz/number"
+)
+
+var (
+	roundedCount int
+	misrounded   int
+)
+
+// verifyRounding checks that inexact results are correctly rounded, without
+// relying on the expected value. The operation is repeated at well over
+// twice the working precision and rounded once to the context precision,
+// which must give the same result. It is done with a single plain call, not
+// computeOnce, so it doesn't share how the result was computed. operands are the operands as the
+// operation saw them, that is after any rounding by the subset arithmetic.
+func verifyRounding(c Context, op string, operands []string, z []*number.Real) {
+	wp, err := workingPrecision(operands)
+	if err != nil {
+		return
+	}
+	w, err := computeAt(op, operands, 2*wp+10)
+	if err != nil {
+		log.Printf("verify-rounding: %v: %v", c, err)
+		return
+	}
+
+	for i := range z {
+		if z[i].Compare(w[i]) == 0 {
+			// exact
+			continue
+		}
+		roundedCount++
+		exact := w[i].String()
+		w[i].SetPrecision(precision)
+		if w[i].String() != z[i].String() {
+			misrounded++
+			log.Printf("misrounded: %v: %v, correctly rounded %v, from %v", c, z[i], w[i], exact)
+		}
+	}
+}